@@ -0,0 +1,144 @@
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"fmt"
+	"sync"
+
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+// AEAD is implemented by our authenticated encryption ciphers, including
+// XChacha. It threads through associated data (aad): bytes that are
+// authenticated but not encrypted, letting callers bind a ciphertext to
+// context such as a user or record ID and detect it being swapped with
+// another record's ciphertext.
+type AEAD interface {
+	// Encrypt seals plaintext under key, authenticating aad alongside it.
+	// aad may be nil.
+	Encrypt(plaintext, aad, key []byte) ([]byte, error)
+	// Decrypt opens a ciphertext produced by Encrypt, failing if key is
+	// wrong or if either the ciphertext or aad do not match what was
+	// sealed.
+	Decrypt(ciphertext, aad, key []byte) ([]byte, error)
+	// KeySize is the size, in bytes, of the key Encrypt and Decrypt expect.
+	KeySize() int
+	// NonceSize is the size, in bytes, of the nonce this cipher prepends
+	// to its ciphertexts.
+	NonceSize() int
+	// Name identifies this cipher for use with Register/Get.
+	Name() string
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]func() AEAD{}
+)
+
+// Register makes an AEAD implementation available under name, so it can
+// later be selected by name (e.g. from configuration) via Get. Registering
+// under a name that is already taken replaces the previous factory.
+func Register(name string, factory func() AEAD) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[name] = factory
+}
+
+// Get returns a new instance of the AEAD implementation registered under
+// name.
+func Get(name string) (AEAD, error) {
+	registryMu.RLock()
+	factory, ok := registry[name]
+	registryMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("crypto: no AEAD registered with name %q", name)
+	}
+	return factory(), nil
+}
+
+func init() {
+	Register("xchacha20poly1305", func() AEAD { return NewXChacha() })
+	Register("chacha20poly1305", func() AEAD { return NewChaCha20Poly1305() })
+	Register("aes-gcm", func() AEAD { return NewAESGCM() })
+}
+
+// NewXChaCha20Poly1305 returns an AEAD backed by XChaCha20-Poly1305. It is
+// an alias for NewXChacha kept for symmetry with NewChaCha20Poly1305 and
+// NewAESGCM.
+func NewXChaCha20Poly1305() AEAD {
+	return NewXChacha()
+}
+
+// chaCha20Poly1305 is an AEAD implementation backed by the original,
+// 96-bit-nonce ChaCha20-Poly1305 (RFC 8439). Prefer NewXChaCha20Poly1305 unless
+// you specifically need interoperability with this narrower nonce variant;
+// its small nonce makes random generation unsafe for very high message
+// volumes under one key.
+type chaCha20Poly1305 struct{}
+
+// NewChaCha20Poly1305 returns an AEAD backed by ChaCha20-Poly1305 with a
+// 96-bit nonce.
+func NewChaCha20Poly1305() AEAD {
+	return chaCha20Poly1305{}
+}
+
+func (chaCha20Poly1305) Encrypt(plaintext, aad, key []byte) ([]byte, error) {
+	aead, err := chacha20poly1305.New(key)
+	if err != nil {
+		return nil, err
+	}
+	return sealWithRandomNonce(aead, plaintext, aad)
+}
+
+func (chaCha20Poly1305) Decrypt(ciphertext, aad, key []byte) ([]byte, error) {
+	aead, err := chacha20poly1305.New(key)
+	if err != nil {
+		return nil, err
+	}
+	return openWithPrependedNonce(aead, ciphertext, aad)
+}
+
+func (chaCha20Poly1305) KeySize() int   { return chacha20poly1305.KeySize }
+func (chaCha20Poly1305) NonceSize() int { return chacha20poly1305.NonceSize }
+func (chaCha20Poly1305) Name() string   { return "chacha20poly1305" }
+
+// aesGCM is an AEAD implementation backed by AES-256-GCM, extracted from
+// what used to be test-only benchmark helpers (encryptAES/decryptAES, formerly
+// in crypto_test.go, whose benchmarks now call this type directly) so it can be used
+// as a real cipher choice and compared against the ChaCha variants on equal
+// footing.
+type aesGCM struct{}
+
+// NewAESGCM returns an AEAD backed by AES-256-GCM.
+func NewAESGCM() AEAD {
+	return aesGCM{}
+}
+
+func (aesGCM) Encrypt(plaintext, aad, key []byte) ([]byte, error) {
+	aead, err := newAESGCMCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return sealWithRandomNonce(aead, plaintext, aad)
+}
+
+func (aesGCM) Decrypt(ciphertext, aad, key []byte) ([]byte, error) {
+	aead, err := newAESGCMCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return openWithPrependedNonce(aead, ciphertext, aad)
+}
+
+func newAESGCMCipher(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func (aesGCM) KeySize() int   { return 32 }
+func (aesGCM) NonceSize() int { return 12 }
+func (aesGCM) Name() string   { return "aes-gcm" }
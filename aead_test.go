@@ -0,0 +1,77 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+)
+
+func testAEADRoundTrip(t *testing.T, aead AEAD) {
+	t.Helper()
+	key := make([]byte, aead.KeySize())
+	if _, err := rand.Read(key); err != nil {
+		t.Fatalf("could not generate key: %s", err)
+	}
+
+	plaintext := []byte("something almost, but not quite entirely unlike tea")
+	aad := []byte("record-42")
+
+	ciphertext, err := aead.Encrypt(plaintext, aad, key)
+	if err != nil {
+		t.Fatalf("Encrypt failed: %s", err)
+	}
+
+	plain, err := aead.Decrypt(ciphertext, aad, key)
+	if err != nil {
+		t.Fatalf("Decrypt failed: %s", err)
+	}
+	if !bytes.Equal(plain, plaintext) {
+		t.Errorf("decrypting the ciphertext did not result in the original plaintext")
+	}
+
+	if _, err := aead.Decrypt(ciphertext, []byte("record-43"), key); err == nil {
+		t.Errorf("did not detect mismatched associated data")
+	}
+}
+
+func TestXChaCha20Poly1305RoundTrip(t *testing.T) {
+	testAEADRoundTrip(t, NewXChaCha20Poly1305())
+}
+
+func TestChaCha20Poly1305RoundTrip(t *testing.T) {
+	testAEADRoundTrip(t, NewChaCha20Poly1305())
+}
+
+func TestAESGCMRoundTrip(t *testing.T) {
+	testAEADRoundTrip(t, NewAESGCM())
+}
+
+func TestRegistryGetReturnsRegisteredCiphers(t *testing.T) {
+	for _, name := range []string{"xchacha20poly1305", "chacha20poly1305", "aes-gcm"} {
+		aead, err := Get(name)
+		if err != nil {
+			t.Errorf("Get(%q) failed: %s", name, err)
+			continue
+		}
+		if aead.Name() != name {
+			t.Errorf("Get(%q) returned a cipher named %q", name, aead.Name())
+		}
+	}
+}
+
+func TestRegistryGetUnknownCipher(t *testing.T) {
+	if _, err := Get("rot13"); err == nil {
+		t.Errorf("did not reject an unregistered cipher name")
+	}
+}
+
+func TestRegisterCustomCipher(t *testing.T) {
+	Register("test-xchacha-alias", func() AEAD { return NewXChaCha20Poly1305() })
+	aead, err := Get("test-xchacha-alias")
+	if err != nil {
+		t.Fatalf("Get failed: %s", err)
+	}
+	if aead.Name() != "xchacha20poly1305" {
+		t.Errorf("got cipher named %q", aead.Name())
+	}
+}
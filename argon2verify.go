@@ -0,0 +1,114 @@
+package crypto
+
+import (
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// ErrMalformedArgon2Hash is returned by VerifyArgon2Encoded when the encoded
+// string is not a well-formed Argon2 PHC string. It is also wrapped in the
+// error returned for a well-formed `$argon2d$` string, which is not supported.
+var ErrMalformedArgon2Hash = errors.New("crypto: malformed argon2 hash")
+
+// VerifyArgon2Encoded verifies password against a PHC-encoded Argon2 hash
+// (`$argon2id$...` or `$argon2i$...`), re-deriving the key with exactly the
+// parameters embedded in encoded rather than those of any particular Argon2
+// instance. This lets a password database keep hashes produced under older,
+// weaker parameters working while still being comparable against the
+// caller's current policy. `$argon2d$` strings are recognized but rejected
+// with an error, since golang.org/x/crypto/argon2 does not implement the
+// Argon2d variant.
+//
+// needsRehash reports whether encoded was produced with weaker parameters
+// than policy, so callers can transparently re-hash the password with
+// policy's parameters once it has been verified.
+func VerifyArgon2Encoded(password []byte, encoded string, policy *Argon2) (equal bool, needsRehash bool, err error) {
+	variant, memoryKB, iterations, threads, salt, key, err := parseArgon2Encoded(encoded)
+	if err != nil {
+		// Pay the same KDF cost we would for a well-formed string so that
+		// how long VerifyArgon2Encoded takes doesn't leak whether encoded
+		// failed to parse or simply didn't match password.
+		payDummyArgon2Cost()
+		return false, false, err
+	}
+
+	var derived []byte
+	switch variant {
+	case "argon2id":
+		derived = argon2.IDKey(password, salt, iterations, memoryKB, threads, uint32(len(key)))
+	case "argon2i":
+		derived = argon2.Key(password, salt, iterations, memoryKB, threads, uint32(len(key)))
+	case "argon2d":
+		return false, false, fmt.Errorf("%w: argon2d is not supported by golang.org/x/crypto/argon2", ErrMalformedArgon2Hash)
+	default:
+		return false, false, fmt.Errorf("%w: unknown variant %q", ErrMalformedArgon2Hash, variant)
+	}
+
+	equal = subtle.ConstantTimeCompare(key, derived) == 1
+	if !equal {
+		return false, false, nil
+	}
+
+	if policy != nil {
+		needsRehash = memoryKB < policy.MemoryKB || iterations < policy.Iterations || threads < policy.Threads
+	}
+	return true, needsRehash, nil
+}
+
+// parseArgon2Encoded parses a PHC-formatted Argon2 hash of the form
+// `$<variant>$v=<version>$m=<memory>,t=<iterations>,p=<threads>$<salt>$<key>`.
+// Every kind of malformation yields the same ErrMalformedArgon2Hash, so the
+// error does not reveal which part of encoded failed to parse.
+func parseArgon2Encoded(encoded string) (variant string, memoryKB, iterations uint32, threads uint8, salt, key []byte, err error) {
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 || parts[0] != "" {
+		return "", 0, 0, 0, nil, nil, ErrMalformedArgon2Hash
+	}
+
+	variant = parts[1]
+	if variant != "argon2id" && variant != "argon2i" && variant != "argon2d" {
+		return "", 0, 0, 0, nil, nil, ErrMalformedArgon2Hash
+	}
+
+	var version int
+	if _, scanErr := fmt.Sscanf(parts[2], "v=%d", &version); scanErr != nil {
+		return "", 0, 0, 0, nil, nil, ErrMalformedArgon2Hash
+	}
+	if version != argon2.Version {
+		return "", 0, 0, 0, nil, nil, ErrMalformedArgon2Hash
+	}
+
+	if _, scanErr := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &memoryKB, &iterations, &threads); scanErr != nil {
+		return "", 0, 0, 0, nil, nil, ErrMalformedArgon2Hash
+	}
+	if validateErr := validateArgon2Params(memoryKB, iterations, threads); validateErr != nil {
+		return "", 0, 0, 0, nil, nil, ErrMalformedArgon2Hash
+	}
+
+	salt, decErr := base64.RawStdEncoding.DecodeString(parts[4])
+	if decErr != nil {
+		return "", 0, 0, 0, nil, nil, ErrMalformedArgon2Hash
+	}
+	key, decErr = base64.RawStdEncoding.DecodeString(parts[5])
+	if decErr != nil {
+		return "", 0, 0, 0, nil, nil, ErrMalformedArgon2Hash
+	}
+
+	return variant, memoryKB, iterations, threads, salt, key, nil
+}
+
+// dummyArgon2Salt is used only to give payDummyArgon2Cost a fixed-size
+// input; its value is not a secret.
+var dummyArgon2Salt = []byte("crypto-timing-mitigation-salt--")[:DefaultArgon2SaltLength]
+
+// payDummyArgon2Cost runs a throwaway Argon2id derivation at our default
+// cost, so that the parse-failure path in VerifyArgon2Encoded takes roughly
+// as long as the path where parsing succeeds and the KDF actually runs.
+func payDummyArgon2Cost() {
+	argon2.IDKey(dummyArgon2Salt, dummyArgon2Salt, DefaultArgon2Iterations, DefaultArgon2MemoryKB, DefaultArgon2Threads, DefaultArgon2KeyLength)
+}
@@ -2,10 +2,7 @@ package crypto
 
 import (
 	"bytes"
-	"crypto/aes"
-	"crypto/cipher"
 	"crypto/rand"
-	"encoding/base64"
 	"fmt"
 	"strings"
 	"testing"
@@ -88,11 +85,11 @@ func TestComparingSHA512Works(t *testing.T) {
 func TestEncryptAndDecryptWork(t *testing.T) {
 	chacha, key := getMeSomeXChacha(t)
 	text := []byte("something almost, but not quite entirely unlike tea")
-	ciphertext, err := chacha.Encrypt(text, key)
+	ciphertext, err := chacha.Encrypt(text, nil, key)
 	if err != nil {
 		t.Error(err)
 	}
-	plain, err := chacha.Decrypt(ciphertext, key)
+	plain, err := chacha.Decrypt(ciphertext, nil, key)
 	if err != nil {
 		t.Error(err)
 	}
@@ -103,7 +100,7 @@ func TestEncryptAndDecryptWork(t *testing.T) {
 
 func TestModifyingCipherTextWithXXChachaFails(t *testing.T) {
 	chacha, key := getMeSomeXChacha(t)
-	ciphertext, err := chacha.Encrypt([]byte("Nothing going to change"), key)
+	ciphertext, err := chacha.Encrypt([]byte("Nothing going to change"), nil, key)
 	if err != nil {
 		t.Error(err)
 	}
@@ -113,7 +110,7 @@ func TestModifyingCipherTextWithXXChachaFails(t *testing.T) {
 	ciphertext[2] = byte(0xAD)
 	ciphertext[3] = byte(0xBE)
 	ciphertext[4] = byte(0xEF)
-	_, err = chacha.Decrypt(ciphertext, key)
+	_, err = chacha.Decrypt(ciphertext, nil, key)
 	if err == nil {
 		t.Errorf("did not detect tampering")
 	}
@@ -172,7 +169,7 @@ func BenchmarkXChachaEncryption(t *testing.B) {
 	bytes := []byte(message)
 	cipher, key := getMeSomeXChacha(nil)
 	for n := 0; n < t.N; n++ {
-		cipher.Encrypt(bytes, key)
+		cipher.Encrypt(bytes, nil, key)
 	}
 }
 
@@ -181,12 +178,12 @@ func BenchmarkXChachaDecryption(t *testing.B) {
 	message := "123e4567-e89b-12d3-a456-426614174000"
 	bytes := []byte(message)
 	cipher, key := getMeSomeXChacha(nil)
-	ciphertext, err := cipher.Encrypt(bytes, key)
+	ciphertext, err := cipher.Encrypt(bytes, nil, key)
 	if err != nil {
 		panic(err)
 	}
 	for n := 0; n < t.N; n++ {
-		cipher.Decrypt(ciphertext, key)
+		cipher.Decrypt(ciphertext, nil, key)
 	}
 }
 
@@ -194,9 +191,11 @@ func BenchmarkAESEncryption(t *testing.B) {
 	t.ReportAllocs()
 	message := "123e4567-e89b-12d3-a456-426614174000"
 	bytes := []byte(message)
-	key, _ := newXChachaKey()
+	aesGCM := NewAESGCM()
+	key := make([]byte, aesGCM.KeySize())
+	rand.Read(key)
 	for n := 0; n < t.N; n++ {
-		encryptAES(bytes, key)
+		aesGCM.Encrypt(bytes, nil, key)
 	}
 }
 
@@ -204,47 +203,18 @@ func BenchmarkAESDecryption(t *testing.B) {
 	t.ReportAllocs()
 	message := "123e4567-e89b-12d3-a456-426614174000"
 	bytes := []byte(message)
-	key, _ := newXChachaKey()
-	ciphertext, err := encryptAES(bytes, key)
+	aesGCM := NewAESGCM()
+	key := make([]byte, aesGCM.KeySize())
+	rand.Read(key)
+	ciphertext, err := aesGCM.Encrypt(bytes, nil, key)
 	if err != nil {
 		panic(err)
 	}
 	for n := 0; n < t.N; n++ {
-		decryptAES(ciphertext, key)
+		aesGCM.Decrypt(ciphertext, nil, key)
 	}
 }
 
-// encryptAES provides AES GCM encryption to be a standard against
-// which we can compare other encryption algorithms
-func encryptAES(msg []byte, key []byte) (string, error) {
-	AESCipher, err := aes.NewCipher(key)
-	if err != nil {
-		return "", err
-	}
-	nonce := make([]byte, 12)
-	if _, err := rand.Read(nonce); err != nil {
-		return "", err
-	}
-	gcm, err := cipher.NewGCM(AESCipher)
-	encryptedMsg := gcm.Seal(nonce, nonce, msg, nil)
-	return base64.StdEncoding.EncodeToString(encryptedMsg), nil
-}
-
-// decryptAES provides AES GCM decryption to be a standard against
-// which we can compare other decryption algorithms
-func decryptAES(encrypted string, key []byte) ([]byte, error) {
-	msg, _ := base64.StdEncoding.DecodeString(encrypted)
-	AESCipher, err := aes.NewCipher(key)
-	if err != nil {
-		return nil, err
-	}
-	gcm, err := cipher.NewGCM(AESCipher)
-	nonce := msg[:gcm.NonceSize()]
-	ciphertext := msg[gcm.NonceSize():]
-	plaintext, err := gcm.Open(nonce, nonce, ciphertext, nil)
-	return plaintext, err
-}
-
 // getMeSomeXChacha returns a new *XChacha instance and a key
 // Panics if t == nil.
 func getMeSomeXChacha(t *testing.T) (*XChacha, []byte) {
@@ -0,0 +1,105 @@
+package crypto
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+// envelopeVersion identifies the binary layout produced by Seal; Open rejects
+// envelopes carrying any other version. The Argon2id parameters recorded in
+// each envelope are what let Open keep decoding older envelopes if the
+// defaults used by Seal change in the future.
+const envelopeVersion = 1
+
+// envelopeSaltSize is the size, in bytes, of the random salt Seal derives a
+// key from.
+const envelopeSaltSize = 16
+
+// Seal encrypts plaintext with a key derived from passphrase using Argon2id,
+// returning a single self-describing envelope that Open can later decrypt
+// without the caller having to remember which KDF parameters were used.
+//
+// The envelope layout is:
+//
+//	1 byte    version
+//	4 bytes   Argon2id memory (KiB), big-endian
+//	4 bytes   Argon2id iterations, big-endian
+//	1 byte    Argon2id parallelism
+//	16 bytes  salt
+//	24 bytes  XChaCha20-Poly1305 nonce
+//	...       ciphertext + Poly1305 tag
+func Seal(passphrase, plaintext []byte) ([]byte, error) {
+	argonParams := NewArgon2()
+
+	salt := make([]byte, envelopeSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	key := argon2.IDKey(passphrase, salt, argonParams.Iterations, argonParams.MemoryKB, argonParams.Threads, argonParams.KeyLength)
+
+	aead, err := chacha20poly1305.NewX(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	envelope := make([]byte, 0, 1+4+4+1+len(salt)+len(nonce)+len(plaintext)+aead.Overhead())
+	envelope = append(envelope, envelopeVersion)
+	envelope = appendUint32(envelope, argonParams.MemoryKB)
+	envelope = appendUint32(envelope, argonParams.Iterations)
+	envelope = append(envelope, argonParams.Threads)
+	envelope = append(envelope, salt...)
+	envelope = append(envelope, nonce...)
+	envelope = aead.Seal(envelope, nonce, plaintext, nil)
+
+	return envelope, nil
+}
+
+// Open decrypts an envelope produced by Seal using passphrase, reconstructing
+// the Argon2id parameters and salt that were recorded in the envelope itself.
+func Open(passphrase, envelope []byte) ([]byte, error) {
+	const headerSize = 1 + 4 + 4 + 1 + envelopeSaltSize + chacha20poly1305.NonceSizeX
+	if len(envelope) < headerSize {
+		return nil, errors.New("crypto: envelope is too short")
+	}
+	if envelope[0] != envelopeVersion {
+		return nil, errors.New("crypto: unsupported envelope version")
+	}
+
+	pos := 1
+	memoryKB := binary.BigEndian.Uint32(envelope[pos:])
+	pos += 4
+	iterations := binary.BigEndian.Uint32(envelope[pos:])
+	pos += 4
+	threads := envelope[pos]
+	pos++
+	salt := envelope[pos : pos+envelopeSaltSize]
+	pos += envelopeSaltSize
+	nonce := envelope[pos : pos+chacha20poly1305.NonceSizeX]
+	pos += chacha20poly1305.NonceSizeX
+	ciphertext := envelope[pos:]
+
+	if err := validateArgon2Params(memoryKB, iterations, threads); err != nil {
+		return nil, err
+	}
+	key := argon2.IDKey(passphrase, salt, iterations, memoryKB, threads, DefaultArgon2KeyLength)
+
+	aead, err := chacha20poly1305.NewX(key)
+	if err != nil {
+		return nil, err
+	}
+	return aead.Open(nil, nonce, ciphertext, nil)
+}
+
+func appendUint32(dst []byte, v uint32) []byte {
+	var buf [4]byte
+	binary.BigEndian.PutUint32(buf[:], v)
+	return append(dst, buf[:]...)
+}
@@ -0,0 +1,91 @@
+package crypto
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+func TestSealAndOpenRoundTrip(t *testing.T) {
+	passphrase := []byte("correct horse battery staple")
+	plaintext := []byte("the devil, having nothing else to do, went about the earth")
+
+	envelope, err := Seal(passphrase, plaintext)
+	if err != nil {
+		t.Fatalf("Seal failed: %s", err)
+	}
+
+	opened, err := Open(passphrase, envelope)
+	if err != nil {
+		t.Fatalf("Open failed: %s", err)
+	}
+
+	if !bytes.Equal(opened, plaintext) {
+		t.Errorf("opened envelope did not match original plaintext")
+	}
+}
+
+func TestOpenFailsWithWrongPassphrase(t *testing.T) {
+	envelope, err := Seal([]byte("right passphrase"), []byte("secret"))
+	if err != nil {
+		t.Fatalf("Seal failed: %s", err)
+	}
+
+	if _, err := Open([]byte("wrong passphrase"), envelope); err == nil {
+		t.Errorf("did not detect a wrong passphrase")
+	}
+}
+
+func TestOpenFailsOnTamperedEnvelope(t *testing.T) {
+	passphrase := []byte("correct horse battery staple")
+	envelope, err := Seal(passphrase, []byte("secret"))
+	if err != nil {
+		t.Fatalf("Seal failed: %s", err)
+	}
+
+	envelope[len(envelope)-1] ^= 0xFF
+
+	if _, err := Open(passphrase, envelope); err == nil {
+		t.Errorf("did not detect tampering with the envelope")
+	}
+}
+
+func TestOpenRejectsTruncatedEnvelope(t *testing.T) {
+	if _, err := Open([]byte("pw"), []byte("too short")); err == nil {
+		t.Errorf("did not reject a truncated envelope")
+	}
+}
+
+func TestOpenRejectsInvalidArgon2Params(t *testing.T) {
+	passphrase := []byte("correct horse battery staple")
+	envelope, err := Seal(passphrase, []byte("secret"))
+	if err != nil {
+		t.Fatalf("Seal failed: %s", err)
+	}
+
+	// Zero out the threads (parallelism) byte: argon2.IDKey panics on a
+	// parallelism of zero, so Open must reject this before calling it.
+	const threadsOffset = 1 + 4 + 4
+	envelope[threadsOffset] = 0
+
+	if _, err := Open(passphrase, envelope); err == nil {
+		t.Errorf("did not reject an envelope with invalid argon2 parameters")
+	}
+}
+
+func TestOpenRejectsOversizedArgon2Memory(t *testing.T) {
+	passphrase := []byte("correct horse battery staple")
+	envelope, err := Seal(passphrase, []byte("secret"))
+	if err != nil {
+		t.Fatalf("Seal failed: %s", err)
+	}
+
+	// A crafted envelope can claim an arbitrarily large memoryKB; Open must
+	// reject it before calling argon2.IDKey rather than trying to honor it.
+	const memoryKBOffset = 1
+	binary.BigEndian.PutUint32(envelope[memoryKBOffset:], 0xFFFFFFFF)
+
+	if _, err := Open(passphrase, envelope); err == nil {
+		t.Errorf("did not reject an envelope with an oversized argon2 memory cost")
+	}
+}
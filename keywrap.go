@@ -0,0 +1,83 @@
+package crypto
+
+import (
+	"crypto/rand"
+	"errors"
+)
+
+// dekSize is the size, in bytes, of the data encryption keys generated by
+// WrapKey.
+const dekSize = 32
+
+// WrapKey generates a fresh data encryption key (dek) and seals it under kek
+// using XChaCha20-Poly1305, returning both the wrapped key and the dek in
+// the clear. Pairing a long-lived kek with many per-message deks avoids
+// exhausting any single key's safe message limit and lets the kek be
+// rotated by re-wrapping deks instead of re-encrypting bulk data.
+func WrapKey(kek []byte) (wrappedKey, dek []byte, err error) {
+	dek = make([]byte, dekSize)
+	if _, err := rand.Read(dek); err != nil {
+		return nil, nil, err
+	}
+
+	wrappedKey, err = NewXChaCha20Poly1305().Encrypt(dek, nil, kek)
+	if err != nil {
+		return nil, nil, err
+	}
+	return wrappedKey, dek, nil
+}
+
+// UnwrapKey reverses WrapKey, recovering the data encryption key sealed
+// under kek.
+func UnwrapKey(kek, wrappedKey []byte) (dek []byte, err error) {
+	return NewXChaCha20Poly1305().Decrypt(wrappedKey, nil, kek)
+}
+
+// EncryptWithWrappedKey performs envelope encryption: it wraps a fresh data
+// encryption key under kek, seals plaintext under that key (authenticating
+// aad alongside it), and returns a single blob of
+// `[2-byte wrapped key length || wrapped key || nonce || ciphertext+tag]`.
+// Callers can encrypt many messages under one long-lived kek, and rotate
+// the kek cheaply by unwrapping each dek and re-wrapping it under a new kek
+// rather than re-encrypting the underlying data.
+func EncryptWithWrappedKey(kek, plaintext, aad []byte) (envelope []byte, err error) {
+	wrappedKey, dek, err := WrapKey(kek)
+	if err != nil {
+		return nil, err
+	}
+	if len(wrappedKey) > 0xFFFF {
+		return nil, errors.New("crypto: wrapped key too large to encode")
+	}
+
+	sealed, err := NewXChaCha20Poly1305().Encrypt(plaintext, aad, dek)
+	if err != nil {
+		return nil, err
+	}
+
+	envelope = make([]byte, 0, 2+len(wrappedKey)+len(sealed))
+	envelope = append(envelope, byte(len(wrappedKey)>>8), byte(len(wrappedKey)))
+	envelope = append(envelope, wrappedKey...)
+	envelope = append(envelope, sealed...)
+	return envelope, nil
+}
+
+// DecryptWithWrappedKey reverses EncryptWithWrappedKey, unwrapping the
+// envelope's data encryption key under kek before using it to open the
+// sealed data.
+func DecryptWithWrappedKey(kek, envelope, aad []byte) ([]byte, error) {
+	if len(envelope) < 2 {
+		return nil, errors.New("crypto: envelope is too short")
+	}
+	wrappedKeyLen := int(envelope[0])<<8 | int(envelope[1])
+	if len(envelope) < 2+wrappedKeyLen {
+		return nil, errors.New("crypto: envelope is too short")
+	}
+	wrappedKey := envelope[2 : 2+wrappedKeyLen]
+	sealed := envelope[2+wrappedKeyLen:]
+
+	dek, err := UnwrapKey(kek, wrappedKey)
+	if err != nil {
+		return nil, err
+	}
+	return NewXChaCha20Poly1305().Decrypt(sealed, aad, dek)
+}
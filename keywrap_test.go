@@ -0,0 +1,77 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+func newKEK(t *testing.T) []byte {
+	t.Helper()
+	kek := make([]byte, chacha20poly1305.KeySize)
+	if _, err := rand.Read(kek); err != nil {
+		t.Fatalf("could not generate kek: %s", err)
+	}
+	return kek
+}
+
+func TestWrapAndUnwrapKeyRoundTrip(t *testing.T) {
+	kek := newKEK(t)
+
+	wrappedKey, dek, err := WrapKey(kek)
+	if err != nil {
+		t.Fatalf("WrapKey failed: %s", err)
+	}
+
+	unwrapped, err := UnwrapKey(kek, wrappedKey)
+	if err != nil {
+		t.Fatalf("UnwrapKey failed: %s", err)
+	}
+	if !bytes.Equal(unwrapped, dek) {
+		t.Errorf("unwrapped key did not match the original dek")
+	}
+}
+
+func TestUnwrapKeyFailsWithWrongKEK(t *testing.T) {
+	wrappedKey, _, err := WrapKey(newKEK(t))
+	if err != nil {
+		t.Fatalf("WrapKey failed: %s", err)
+	}
+
+	if _, err := UnwrapKey(newKEK(t), wrappedKey); err == nil {
+		t.Errorf("did not detect the wrong kek")
+	}
+}
+
+func TestEncryptAndDecryptWithWrappedKeyRoundTrip(t *testing.T) {
+	kek := newKEK(t)
+	plaintext := []byte("the first rule of envelope encryption is you do not re-encrypt the data")
+	aad := []byte("record-7")
+
+	envelope, err := EncryptWithWrappedKey(kek, plaintext, aad)
+	if err != nil {
+		t.Fatalf("EncryptWithWrappedKey failed: %s", err)
+	}
+
+	decrypted, err := DecryptWithWrappedKey(kek, envelope, aad)
+	if err != nil {
+		t.Fatalf("DecryptWithWrappedKey failed: %s", err)
+	}
+	if !bytes.Equal(decrypted, plaintext) {
+		t.Errorf("decrypted data did not match the original plaintext")
+	}
+}
+
+func TestDecryptWithWrappedKeyFailsOnMismatchedAAD(t *testing.T) {
+	kek := newKEK(t)
+	envelope, err := EncryptWithWrappedKey(kek, []byte("secret"), []byte("record-7"))
+	if err != nil {
+		t.Fatalf("EncryptWithWrappedKey failed: %s", err)
+	}
+
+	if _, err := DecryptWithWrappedKey(kek, envelope, []byte("record-8")); err == nil {
+		t.Errorf("did not detect mismatched associated data")
+	}
+}
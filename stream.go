@@ -0,0 +1,140 @@
+package crypto
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+// streamChunkSize is the size, in plaintext bytes, of each segment sealed by
+// EncryptStream. It is small enough to keep memory use bounded while large
+// enough to amortize the per-chunk AEAD overhead.
+const streamChunkSize = 64 * 1024
+
+// streamNoncePrefixSize is the portion of the 24-byte XChaCha nonce that is
+// chosen at random once per stream; the remaining bytes carry the chunk
+// counter and the "last chunk" flag.
+const streamNoncePrefixSize = chacha20poly1305.NonceSizeX - 4 - 1
+
+// streamNonce builds the per-chunk nonce used by EncryptStream/DecryptStream:
+// the stream's random prefix, followed by a big-endian chunk counter and a
+// single byte that is 0x01 on the final chunk and 0x00 otherwise.
+func streamNonce(prefix []byte, counter uint32, last bool) []byte {
+	nonce := make([]byte, 0, chacha20poly1305.NonceSizeX)
+	nonce = append(nonce, prefix...)
+	var counterBytes [4]byte
+	binary.BigEndian.PutUint32(counterBytes[:], counter)
+	nonce = append(nonce, counterBytes[:]...)
+	if last {
+		nonce = append(nonce, 0x01)
+	} else {
+		nonce = append(nonce, 0x00)
+	}
+	return nonce
+}
+
+// EncryptStream implements the STREAM construction (Rogaway/Hoang-Reyhanitabar-
+// Rogaway-Vizár) on top of XChaCha20-Poly1305: it reads src in streamChunkSize
+// segments, seals each one under a nonce derived from a random per-stream
+// prefix plus a monotonic counter and a "last chunk" flag, and writes the
+// prefix followed by the sealed chunks to dst. This lets callers encrypt
+// payloads larger than memory without buffering the whole plaintext or
+// ciphertext.
+func (x *XChacha) EncryptStream(dst io.Writer, src io.Reader, key []byte) error {
+	aead, err := newXChaChaCipher(key)
+	if err != nil {
+		return err
+	}
+
+	prefix := make([]byte, streamNoncePrefixSize)
+	if _, err := rand.Read(prefix); err != nil {
+		return err
+	}
+	if _, err := dst.Write(prefix); err != nil {
+		return err
+	}
+
+	chunk := make([]byte, streamChunkSize)
+	n, err := io.ReadFull(src, chunk)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return err
+	}
+	chunk = chunk[:n]
+
+	var counter uint32
+	for {
+		next := make([]byte, streamChunkSize)
+		m, nextErr := io.ReadFull(src, next)
+		if nextErr != nil && nextErr != io.ErrUnexpectedEOF && nextErr != io.EOF {
+			return nextErr
+		}
+		last := m == 0
+
+		if !last && counter == ^uint32(0) {
+			return errors.New("crypto: stream has too many chunks for a single nonce space")
+		}
+
+		sealed := aead.Seal(nil, streamNonce(prefix, counter, last), chunk, nil)
+		if _, err := dst.Write(sealed); err != nil {
+			return err
+		}
+		if last {
+			return nil
+		}
+		counter++
+		chunk = next[:m]
+	}
+}
+
+// DecryptStream reverses EncryptStream: it reads the stream's nonce prefix
+// followed by sealed chunks from src, opens each chunk in order, and writes
+// the recovered plaintext to dst. It returns an error if any chunk fails
+// authentication, or if the stream ends without a chunk carrying the "last
+// chunk" flag, which would otherwise let an attacker truncate the stream
+// undetected.
+func (x *XChacha) DecryptStream(dst io.Writer, src io.Reader, key []byte) error {
+	aead, err := newXChaChaCipher(key)
+	if err != nil {
+		return err
+	}
+
+	prefix := make([]byte, streamNoncePrefixSize)
+	if _, err := io.ReadFull(src, prefix); err != nil {
+		return fmt.Errorf("crypto: reading stream prefix: %w", err)
+	}
+
+	sealedSize := streamChunkSize + aead.Overhead()
+	sealed := make([]byte, sealedSize)
+	n, err := io.ReadFull(src, sealed)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return err
+	}
+	sealed = sealed[:n]
+
+	var counter uint32
+	for {
+		next := make([]byte, sealedSize)
+		m, nextErr := io.ReadFull(src, next)
+		if nextErr != nil && nextErr != io.ErrUnexpectedEOF && nextErr != io.EOF {
+			return nextErr
+		}
+		last := m == 0
+
+		plain, err := aead.Open(nil, streamNonce(prefix, counter, last), sealed, nil)
+		if err != nil {
+			return errors.New("crypto: chunk authentication failed")
+		}
+		if _, err := dst.Write(plain); err != nil {
+			return err
+		}
+		if last {
+			return nil
+		}
+		counter++
+		sealed = next[:m]
+	}
+}
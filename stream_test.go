@@ -0,0 +1,80 @@
+package crypto
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestStreamEncryptDecryptRoundTrip(t *testing.T) {
+	chacha, key := getMeSomeXChacha(t)
+
+	plaintext := bytes.Repeat([]byte("a"), streamChunkSize*2+123)
+
+	var ciphertext bytes.Buffer
+	if err := chacha.EncryptStream(&ciphertext, bytes.NewReader(plaintext), key); err != nil {
+		t.Fatalf("EncryptStream failed: %s", err)
+	}
+
+	var decrypted bytes.Buffer
+	if err := chacha.DecryptStream(&decrypted, &ciphertext, key); err != nil {
+		t.Fatalf("DecryptStream failed: %s", err)
+	}
+
+	if !bytes.Equal(decrypted.Bytes(), plaintext) {
+		t.Errorf("decrypted stream did not match original plaintext")
+	}
+}
+
+func TestStreamEncryptDecryptEmpty(t *testing.T) {
+	chacha, key := getMeSomeXChacha(t)
+
+	var ciphertext bytes.Buffer
+	if err := chacha.EncryptStream(&ciphertext, strings.NewReader(""), key); err != nil {
+		t.Fatalf("EncryptStream failed: %s", err)
+	}
+
+	var decrypted bytes.Buffer
+	if err := chacha.DecryptStream(&decrypted, &ciphertext, key); err != nil {
+		t.Fatalf("DecryptStream failed: %s", err)
+	}
+
+	if decrypted.Len() != 0 {
+		t.Errorf("expected empty plaintext, got %d bytes", decrypted.Len())
+	}
+}
+
+func TestStreamDetectsTamperedChunk(t *testing.T) {
+	chacha, key := getMeSomeXChacha(t)
+	plaintext := bytes.Repeat([]byte("b"), streamChunkSize+10)
+
+	var ciphertext bytes.Buffer
+	if err := chacha.EncryptStream(&ciphertext, bytes.NewReader(plaintext), key); err != nil {
+		t.Fatalf("EncryptStream failed: %s", err)
+	}
+
+	tampered := ciphertext.Bytes()
+	tampered[len(tampered)-1] ^= 0xFF
+
+	var decrypted bytes.Buffer
+	if err := chacha.DecryptStream(&decrypted, bytes.NewReader(tampered), key); err == nil {
+		t.Errorf("did not detect tampering with the final chunk")
+	}
+}
+
+func TestStreamDetectsTruncation(t *testing.T) {
+	chacha, key := getMeSomeXChacha(t)
+	plaintext := bytes.Repeat([]byte("c"), streamChunkSize*2)
+
+	var ciphertext bytes.Buffer
+	if err := chacha.EncryptStream(&ciphertext, bytes.NewReader(plaintext), key); err != nil {
+		t.Fatalf("EncryptStream failed: %s", err)
+	}
+
+	truncated := ciphertext.Bytes()[:streamNoncePrefixSize+streamChunkSize/2]
+
+	var decrypted bytes.Buffer
+	if err := chacha.DecryptStream(&decrypted, bytes.NewReader(truncated), key); err == nil {
+		t.Errorf("did not detect a truncated stream")
+	}
+}